@@ -2,22 +2,44 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
 	"os/exec"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/montanaflynn/stats" // For percentile calculations
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Latency histogram bounds: 1 microsecond to 60 seconds, recorded in
+// nanoseconds, at 3 significant figures of precision.
+const (
+	minLatencyNanos int64 = 1000
+	maxLatencyNanos int64 = 60 * 1000 * 1000 * 1000
+	latencySigFigs        = 3
 )
 
 // OperationResult stores the outcome of a single command execution.
 type OperationResult struct {
+	// Latency is the "corrected" latency: time.Since(the send time this
+	// operation was scheduled for). In closed-loop mode the scheduled time
+	// is the actual send time, so this equals ActualLatency. In -openLoop
+	// mode it also captures any queueing delay caused by a slow target,
+	// which is what coordinated-omission correction is about.
 	Latency time.Duration
-	Error   error
+	// ActualLatency is the raw, uncorrected service time: time from when the
+	// operation actually started to when it completed.
+	ActualLatency time.Duration
+	Error         error
 }
 
 // BenchmarkStats holds all calculated statistics.
@@ -45,6 +67,19 @@ var (
 	totalOps       *int64
 	parallel       *int
 	reportInterval *time.Duration
+
+	driver        *string
+	pipelineN     *int
+	latencyMode   *string
+	redisAddr     *string
+	redisPassword *string
+
+	openLoop *bool
+	hdrOut   *string
+
+	outputFormat   *string
+	prometheusAddr *string
+	summaryFile    *string
 )
 
 func parseFlags() {
@@ -55,6 +90,19 @@ func parseFlags() {
 	parallel = flag.Int("parallel", 1, "Number of parallel workers/goroutines")
 	reportInterval = flag.Duration("reportInterval", 5*time.Second, "How often to report metrics during the run (e.g., \"5s\", 0 to disable)")
 
+	driver = flag.String("driver", "native", "Execution driver: native (go-redis client) or subprocess (exec.CommandContext)")
+	pipelineN = flag.Int("pipeline", 1, "Commands batched per round trip in -driver=native (1 disables pipelining)")
+	latencyMode = flag.String("latencyMode", "per-cmd", "How to attribute pipelined latency: per-cmd|per-batch")
+	redisAddr = flag.String("redisAddr", "localhost:6379", "Redis address for -driver=native")
+	redisPassword = flag.String("redisPassword", "", "Redis password for -driver=native")
+
+	openLoop = flag.Bool("openLoop", false, "Schedule send times up front (start + i/rps) and measure latency from the scheduled time, correcting for coordinated omission")
+	hdrOut = flag.String("hdrOut", "", "Dump the corrected latency histogram in HdrHistogram percentile distribution format to this file, for offline plotting")
+
+	outputFormat = flag.String("outputFormat", "text", "Periodic report format: text|json|jsonl")
+	prometheusAddr = flag.String("prometheusAddr", "", "If set (e.g. \":9100\"), serve Prometheus metrics at /metrics on this address")
+	summaryFile = flag.String("summaryFile", "", "Write the final summary as a JSON blob to this file")
+
 	flag.Parse()
 
 	if *targetCmdStr == "" {
@@ -73,9 +121,77 @@ func parseFlags() {
 		log.Fatalln("Error: -reportInterval cannot be negative.")
 		flag.Usage()
 	}
+	if *driver != "native" && *driver != "subprocess" {
+		log.Fatalln("Error: -driver must be native or subprocess.")
+		flag.Usage()
+	}
+	if *pipelineN <= 0 {
+		log.Fatalln("Error: -pipeline must be greater than 0.")
+		flag.Usage()
+	}
+	if *pipelineN > 1 && *driver != "native" {
+		log.Fatalln("Error: -pipeline requires -driver=native.")
+		flag.Usage()
+	}
+	if *latencyMode != "per-cmd" && *latencyMode != "per-batch" {
+		log.Fatalln("Error: -latencyMode must be per-cmd or per-batch.")
+		flag.Usage()
+	}
+	if *openLoop && *rps <= 0 {
+		log.Fatalln("Error: -openLoop requires -rps > 0 to compute a send schedule.")
+		flag.Usage()
+	}
+	if *outputFormat != "text" && *outputFormat != "json" && *outputFormat != "jsonl" {
+		log.Fatalln("Error: -outputFormat must be text, json, or jsonl.")
+		flag.Usage()
+	}
 }
 
-func worker(ctx context.Context, wg *sync.WaitGroup, workerID int, cmdParts []string, resultsChan chan<- OperationResult, workerRPSLimit int) {
+// parseCmdArgs tokenizes -cmd into RESP arguments, honoring single- or
+// double-quoted tokens (e.g. `SET key "hello world"`) the way strings.Fields
+// alone cannot.
+func parseCmdArgs(s string) []string {
+	var args []string
+	var cur strings.Builder
+	inQuotes := false
+	var quoteChar byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case inQuotes:
+			if c == quoteChar {
+				inQuotes = false
+			} else {
+				cur.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			inQuotes = true
+			quoteChar = c
+		case c == ' ' || c == '\t':
+			if cur.Len() > 0 {
+				args = append(args, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	if cur.Len() > 0 {
+		args = append(args, cur.String())
+	}
+	return args
+}
+
+func toInterfaceArgs(args []string) []interface{} {
+	ifaceArgs := make([]interface{}, len(args))
+	for i, a := range args {
+		ifaceArgs[i] = a
+	}
+	return ifaceArgs
+}
+
+func worker(ctx context.Context, wg *sync.WaitGroup, workerID int, cmdParts []string, rdb *redis.Client, resultsChan chan<- OperationResult, workerRPSLimit int) {
 	defer wg.Done()
 	var ticker *time.Ticker
 	if workerRPSLimit > 0 {
@@ -101,25 +217,185 @@ func worker(ctx context.Context, wg *sync.WaitGroup, workerID int, cmdParts []st
 		default:
 		}
 
-		startTime := time.Now()
-		var cmd *exec.Cmd
-		if len(cmdParts) > 1 {
-			cmd = exec.CommandContext(ctx, cmdParts[0], cmdParts[1:]...)
+		sendTime := time.Now()
+		if *driver == "native" {
+			runNativeRound(ctx, cmdParts, rdb, sendTime, resultsChan)
 		} else {
-			cmd = exec.CommandContext(ctx, cmdParts[0])
+			runSubprocessOp(ctx, cmdParts, sendTime, resultsChan)
+		}
+	}
+}
+
+// runOpenLoopWorker drives -openLoop mode: rather than pacing itself with a
+// ticker, it pulls pre-computed send times off a schedule channel shared by
+// all workers, so a slow target can't hide behind tick-starved workers.
+func runOpenLoopWorker(ctx context.Context, wg *sync.WaitGroup, cmdParts []string, rdb *redis.Client, scheduleChan <-chan time.Time, resultsChan chan<- OperationResult) {
+	defer wg.Done()
+	for {
+		select {
+		case sendTime, ok := <-scheduleChan:
+			if !ok {
+				return
+			}
+			if *driver == "native" {
+				runNativeRound(ctx, cmdParts, rdb, sendTime, resultsChan)
+			} else {
+				runSubprocessOp(ctx, cmdParts, sendTime, resultsChan)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runScheduler feeds scheduleChan with send times spaced at start + i*(1/rps),
+// the open-loop request generation wrk2 popularized: requests are due at a
+// fixed schedule regardless of how long the previous one took.
+func runScheduler(ctx context.Context, start time.Time, scheduleChan chan<- time.Time) {
+	defer close(scheduleChan)
+	interval := time.Second / time.Duration(*rps)
+
+	var i int64
+	for {
+		if *totalOps > 0 && i >= *totalOps {
+			return
+		}
+		sendTime := start.Add(time.Duration(i) * interval)
+		if wait := time.Until(sendTime); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+		select {
+		case scheduleChan <- sendTime:
+		case <-ctx.Done():
+			return
+		}
+		i++
+	}
+}
+
+// runSubprocessOp executes -cmd via exec.CommandContext, the original driver
+// kept around for commands that aren't Redis commands go-redis can issue.
+func runSubprocessOp(ctx context.Context, cmdParts []string, sendTime time.Time, resultsChan chan<- OperationResult) {
+	startTime := time.Now()
+	var cmd *exec.Cmd
+	if len(cmdParts) > 1 {
+		cmd = exec.CommandContext(ctx, cmdParts[0], cmdParts[1:]...)
+	} else {
+		cmd = exec.CommandContext(ctx, cmdParts[0])
+	}
+	_, err := cmd.Output()
+	actualLatency := time.Since(startTime)
+	correctedLatency := time.Since(sendTime)
+	opResult := OperationResult{Latency: correctedLatency, ActualLatency: actualLatency, Error: err}
+	select {
+	case resultsChan <- opResult:
+	case <-ctx.Done():
+	}
+}
+
+// runNativeRound issues -cmd against the shared go-redis client, batching
+// *pipelineN commands per round trip when pipelining is enabled.
+func runNativeRound(ctx context.Context, cmdParts []string, rdb *redis.Client, sendTime time.Time, resultsChan chan<- OperationResult) {
+	args := toInterfaceArgs(cmdParts)
+	n := *pipelineN
+
+	if n == 1 {
+		startTime := time.Now()
+		_, err := rdb.Do(ctx, args...).Result()
+		actualLatency := time.Since(startTime)
+		correctedLatency := time.Since(sendTime)
+		select {
+		case resultsChan <- OperationResult{Latency: correctedLatency, ActualLatency: actualLatency, Error: err}:
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	pipe := rdb.Pipeline()
+	for i := 0; i < n; i++ {
+		pipe.Do(ctx, args...)
+	}
+	batchStart := time.Now()
+	_, err := pipe.Exec(ctx)
+	actualBatchLatency := time.Since(batchStart)
+	correctedBatchLatency := time.Since(sendTime)
+
+	if *latencyMode == "per-batch" {
+		select {
+		case resultsChan <- OperationResult{Latency: correctedBatchLatency, ActualLatency: actualBatchLatency, Error: err}:
+		case <-ctx.Done():
 		}
-		_, err := cmd.Output()
-		latency := time.Since(startTime)
-		opResult := OperationResult{Latency: latency, Error: err}
+		return
+	}
+
+	perCmdActualLatency := actualBatchLatency / time.Duration(n)
+	perCmdCorrectedLatency := correctedBatchLatency / time.Duration(n)
+	for i := 0; i < n; i++ {
 		select {
-		case resultsChan <- opResult:
+		case resultsChan <- OperationResult{Latency: perCmdCorrectedLatency, ActualLatency: perCmdActualLatency, Error: err}:
 		case <-ctx.Done():
 			return
 		}
 	}
 }
 
-func periodicReporter(ctx context.Context, benchmarkStartTime time.Time, currentTotalOps *int64) {
+// windowedStats accumulates latencies and failures since the last periodic
+// report, so each report reflects only its own interval rather than the
+// whole run to date — a cumulative view hides a regression that recovered
+// before the final summary printed.
+type windowedStats struct {
+	mu       sync.Mutex
+	hist     *hdrhistogram.Histogram
+	ops      int64
+	failures int64
+}
+
+func newWindowedStats() *windowedStats {
+	return &windowedStats{hist: hdrhistogram.New(minLatencyNanos, maxLatencyNanos, latencySigFigs)}
+}
+
+func (w *windowedStats) record(latency time.Duration, failed bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	recordLatency(w.hist, latency)
+	w.ops++
+	if failed {
+		w.failures++
+	}
+}
+
+// snapshotAndReset returns the interval's histogram, op count, and failure
+// count, then starts a fresh window.
+func (w *windowedStats) snapshotAndReset() (*hdrhistogram.Histogram, int64, int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	hist, ops, failures := w.hist, w.ops, w.failures
+	w.hist = hdrhistogram.New(minLatencyNanos, maxLatencyNanos, latencySigFigs)
+	w.ops, w.failures = 0, 0
+	return hist, ops, failures
+}
+
+// intervalReport is one periodic report in -outputFormat=json|jsonl, built
+// from the windowed (not cumulative) histogram.
+type intervalReport struct {
+	Timestamp   string  `json:"timestamp"`
+	IntervalOps int64   `json:"interval_ops"`
+	IntervalRPS float64 `json:"interval_rps"`
+	OverallRPS  float64 `json:"overall_rps"`
+	P50Nanos    int64   `json:"p50_ns"`
+	P95Nanos    int64   `json:"p95_ns"`
+	P99Nanos    int64   `json:"p99_ns"`
+	P999Nanos   int64   `json:"p999_ns"`
+	Failures    int64   `json:"failures"`
+}
+
+func periodicReporter(ctx context.Context, benchmarkStartTime time.Time, currentTotalOps *int64, window *windowedStats) {
 	if *reportInterval == 0 {
 		return
 	}
@@ -127,7 +403,9 @@ func periodicReporter(ctx context.Context, benchmarkStartTime time.Time, current
 	defer ticker.Stop()
 	lastReportTime := benchmarkStartTime
 	var lastReportOps int64 = 0
-	fmt.Println("\n--- Periodic Reports ---")
+	if *outputFormat == "text" {
+		fmt.Println("\n--- Periodic Reports ---")
+	}
 	for {
 		select {
 		case <-ticker.C:
@@ -144,8 +422,32 @@ func periodicReporter(ctx context.Context, benchmarkStartTime time.Time, current
 			if overallDuration.Seconds() > 0 {
 				overallOpsPerSecond = float64(currentOps) / overallDuration.Seconds()
 			}
-			fmt.Printf("[%s] Current: %.2f ops/s | Total Ops: %d | Overall Avg: %.2f ops/s\n",
-				time.Now().Format("15:04:05"), intervalOpsPerSecond, currentOps, overallOpsPerSecond)
+			hist, _, windowFailures := window.snapshotAndReset()
+
+			switch *outputFormat {
+			case "text":
+				fmt.Printf("[%s] Current: %.2f ops/s | Total Ops: %d | Overall Avg: %.2f ops/s\n",
+					time.Now().Format("15:04:05"), intervalOpsPerSecond, currentOps, overallOpsPerSecond)
+			case "json", "jsonl":
+				report := intervalReport{
+					Timestamp:   currentTime.Format(time.RFC3339),
+					IntervalOps: intervalOps,
+					IntervalRPS: intervalOpsPerSecond,
+					OverallRPS:  overallOpsPerSecond,
+					P50Nanos:    hist.ValueAtQuantile(50),
+					P95Nanos:    hist.ValueAtQuantile(95),
+					P99Nanos:    hist.ValueAtQuantile(99),
+					P999Nanos:   hist.ValueAtQuantile(99.9),
+					Failures:    windowFailures,
+				}
+				if *outputFormat == "jsonl" {
+					if b, err := json.Marshal(report); err == nil {
+						fmt.Println(string(b))
+					}
+				} else if b, err := json.MarshalIndent(report, "", "  "); err == nil {
+					fmt.Println(string(b))
+				}
+			}
 			lastReportTime = currentTime
 			lastReportOps = currentOps
 		case <-ctx.Done():
@@ -155,50 +457,142 @@ func periodicReporter(ctx context.Context, benchmarkStartTime time.Time, current
 	}
 }
 
-// calculateLatencyStats calculates various latency metrics from a slice of durations.
-func calculateLatencyStats(latenciesNanos []float64) (
-	min time.Duration, max time.Duration, mean time.Duration,
-	p50 time.Duration, p95 time.Duration, p99 time.Duration, p999 time.Duration, err error) {
+// promMetrics holds the Prometheus collectors exposed at /metrics when
+// -prometheusAddr is set.
+type promMetrics struct {
+	opsTotal       prometheus.Counter
+	failuresTotal  prometheus.Counter
+	latencySeconds prometheus.Histogram
+}
 
-	if len(latenciesNanos) == 0 {
-		err = fmt.Errorf("no latencies to calculate statistics from")
-		return
+// startPrometheusServer registers the benchmark's metrics on their own
+// registry and serves them at addr + "/metrics".
+func startPrometheusServer(addr string) *promMetrics {
+	m := &promMetrics{
+		opsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "benchmark_ops_total", Help: "Total operations attempted.",
+		}),
+		failuresTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "benchmark_failures_total", Help: "Total operations that returned an error.",
+		}),
+		latencySeconds: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "benchmark_latency_seconds", Help: "Corrected operation latency, in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}),
 	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m.opsTotal, m.failuresTotal, m.latencySeconds)
 
-	// Min, Max, Mean can be calculated directly or using the stats package
-	minVal, _ := stats.Min(latenciesNanos)
-	maxVal, _ := stats.Max(latenciesNanos)
-	meanVal, _ := stats.Mean(latenciesNanos)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	server := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Prometheus server error: %v", err)
+		}
+	}()
+	return m
+}
 
-	min = time.Duration(minVal)
-	max = time.Duration(maxVal)
-	mean = time.Duration(meanVal)
+// finalSummary is the -summaryFile JSON blob, letting CI gate a run on
+// regressions without scraping stdout.
+type finalSummary struct {
+	TotalOps        int64   `json:"total_ops"`
+	SuccessfulOps   int64   `json:"successful_ops"`
+	FailedOps       int64   `json:"failed_ops"`
+	DurationSeconds float64 `json:"duration_seconds"`
+	OverallRPS      float64 `json:"overall_rps"`
+	MinNanos        int64   `json:"min_ns"`
+	MaxNanos        int64   `json:"max_ns"`
+	MeanNanos       int64   `json:"mean_ns"`
+	P50Nanos        int64   `json:"p50_ns"`
+	P95Nanos        int64   `json:"p95_ns"`
+	P99Nanos        int64   `json:"p99_ns"`
+	P999Nanos       int64   `json:"p999_ns"`
+}
 
-	p50Val, err := stats.Percentile(latenciesNanos, 50)
-	if err != nil {
-		return min, max, mean, 0, 0, 0, 0, fmt.Errorf("failed to calculate p50: %w", err)
-	}
-	p95Val, err := stats.Percentile(latenciesNanos, 95)
+func writeSummaryFile(path string, summary finalSummary) error {
+	f, err := os.Create(path)
 	if err != nil {
-		return min, max, mean, 0, 0, 0, 0, fmt.Errorf("failed to calculate p95: %w", err)
+		return fmt.Errorf("creating -summaryFile: %w", err)
 	}
-	p99Val, err := stats.Percentile(latenciesNanos, 99)
-	if err != nil {
-		return min, max, mean, 0, 0, 0, 0, fmt.Errorf("failed to calculate p99: %w", err)
+	defer f.Close()
+	return json.NewEncoder(f).Encode(summary)
+}
+
+// recordLatency clamps a duration into the histogram's configured range and
+// records it, nanoseconds-valued. Clamping trades a sliver of accuracy at the
+// extremes for never dropping a sample because it fell outside [1us, 60s].
+func recordLatency(h *hdrhistogram.Histogram, d time.Duration) {
+	v := d.Nanoseconds()
+	if v < minLatencyNanos {
+		v = minLatencyNanos
 	}
-	p999Val, err := stats.Percentile(latenciesNanos, 99.9)
-	if err != nil {
-		return min, max, mean, 0, 0, 0, 0, fmt.Errorf("failed to calculate p99.9: %w", err)
+	if v > maxLatencyNanos {
+		v = maxLatencyNanos
 	}
+	_ = h.RecordValue(v)
+}
 
-	p50 = time.Duration(p50Val)
-	p95 = time.Duration(p95Val)
-	p99 = time.Duration(p99Val)
-	p999 = time.Duration(p999Val)
+// calculateLatencyStats pulls the headline latency stats out of an HDR
+// histogram. Unlike the old RawLatencies-slice approach, this is O(1) memory
+// regardless of how many operations were recorded.
+func calculateLatencyStats(h *hdrhistogram.Histogram) (
+	min time.Duration, max time.Duration, mean time.Duration,
+	p50 time.Duration, p95 time.Duration, p99 time.Duration, p999 time.Duration, err error) {
+
+	if h.TotalCount() == 0 {
+		err = fmt.Errorf("no latencies to calculate statistics from")
+		return
+	}
 
+	min = time.Duration(h.Min())
+	max = time.Duration(h.Max())
+	mean = time.Duration(int64(h.Mean()))
+	p50 = time.Duration(h.ValueAtQuantile(50))
+	p95 = time.Duration(h.ValueAtQuantile(95))
+	p99 = time.Duration(h.ValueAtQuantile(99))
+	p999 = time.Duration(h.ValueAtQuantile(99.9))
 	return
 }
 
+// printLatencyDistribution prints a compact ASCII percentile table with a
+// corrected and an uncorrected column side by side, in the spirit of wrk2's
+// latency distribution report.
+func printLatencyDistribution(corrected, uncorrected *hdrhistogram.Histogram) {
+	fmt.Println("\n--- Latency Distribution ---")
+	fmt.Printf("  %-10s %14s %14s\n", "Percentile", "Corrected", "Uncorrected")
+	for _, p := range []float64{50, 75, 90, 95, 99, 99.9, 99.99} {
+		label := fmt.Sprintf("p%g", p)
+		c := time.Duration(corrected.ValueAtQuantile(p))
+		u := time.Duration(uncorrected.ValueAtQuantile(p))
+		fmt.Printf("  %-10s %14s %14s\n", label, c, u)
+	}
+	fmt.Printf("  %-10s %14s %14s\n", "max",
+		time.Duration(corrected.Max()), time.Duration(uncorrected.Max()))
+}
+
+// hdrLogTicksPerHalfDistance matches the default percentile-ticks-per-half-
+// distance ("-5" in most HdrHistogram CLIs) used by HdrHistogram's own
+// percentile distribution printer, so output lines up with what other
+// HdrHistogram tooling produces for the same data.
+const hdrLogTicksPerHalfDistance = 5
+
+// writeHdrLog writes the corrected latency histogram's percentile
+// distribution via the hdrhistogram-go library's own PercentilesPrint, the
+// standard HdrHistogram text format HdrHistogram-aware plotting tools parse.
+// Values are scaled from nanoseconds to milliseconds.
+func writeHdrLog(path string, h *hdrhistogram.Histogram) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating -hdrOut file: %w", err)
+	}
+	defer f.Close()
+
+	_, err = h.PercentilesPrint(f, hdrLogTicksPerHalfDistance, 1e6)
+	return err
+}
+
 func main() {
 	parseFlags()
 
@@ -212,21 +606,55 @@ func main() {
 	}
 	fmt.Printf("  Parallel Workers: %d\n", *parallel)
 	fmt.Printf("  Report Interval: %s (0 to disable)\n", reportInterval.String())
+	fmt.Printf("  Driver: %s\n", *driver)
+	if *driver == "native" {
+		fmt.Printf("  Pipeline: %d (latencyMode=%s)\n", *pipelineN, *latencyMode)
+	}
+	if *openLoop {
+		fmt.Println("  Open-loop: scheduled send times, coordinated-omission corrected")
+	}
+	fmt.Printf("  Output format: %s\n", *outputFormat)
+	if *prometheusAddr != "" {
+		fmt.Printf("  Prometheus metrics: http://%s/metrics\n", *prometheusAddr)
+	}
 
-	cmdParts := strings.Fields(*targetCmdStr)
+	cmdParts := parseCmdArgs(*targetCmdStr)
 	if len(cmdParts) == 0 {
 		log.Fatalln("Error: Command string is empty after parsing.")
 	}
 
+	var rdb *redis.Client
+	if *driver == "native" {
+		rdb = redis.NewClient(&redis.Options{
+			Addr:     *redisAddr,
+			Password: *redisPassword,
+			PoolSize: *parallel + 5,
+		})
+		defer rdb.Close()
+		ctx := context.Background()
+		if _, err := rdb.Ping(ctx).Result(); err != nil {
+			log.Fatalf("Could not connect to Redis at %s: %v", *redisAddr, err)
+		}
+		fmt.Println("Successfully connected to Redis at", *redisAddr)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	resultsChan := make(chan OperationResult, *parallel*10) // Buffer size can be tuned
 	var wg sync.WaitGroup
 	var collectedOpsAtomic int64
-	var successfulOpsAtomic int64            // For successful ops count
-	var failedOpsAtomic int64                // For failed ops count
-	allLatencies := make([]time.Duration, 0) // Initialize to avoid nil if no ops
+	var successfulOpsAtomic int64 // For successful ops count
+	var failedOpsAtomic int64     // For failed ops count
+
+	correctedHist := hdrhistogram.New(minLatencyNanos, maxLatencyNanos, latencySigFigs)
+	uncorrectedHist := hdrhistogram.New(minLatencyNanos, maxLatencyNanos, latencySigFigs)
+	window := newWindowedStats()
+
+	var metrics *promMetrics
+	if *prometheusAddr != "" {
+		metrics = startPrometheusServer(*prometheusAddr)
+	}
 
 	workerRPSLimit := 0
 	if *rps > 0 && *parallel > 0 {
@@ -238,12 +666,21 @@ func main() {
 	benchmarkStartTime := time.Now()
 
 	if *reportInterval > 0 {
-		go periodicReporter(ctx, benchmarkStartTime, &collectedOpsAtomic)
+		go periodicReporter(ctx, benchmarkStartTime, &collectedOpsAtomic, window)
 	}
 
-	for i := 0; i < *parallel; i++ {
-		wg.Add(1)
-		go worker(ctx, &wg, i, cmdParts, resultsChan, workerRPSLimit)
+	if *openLoop {
+		scheduleChan := make(chan time.Time, *parallel*2)
+		go runScheduler(ctx, benchmarkStartTime, scheduleChan)
+		for i := 0; i < *parallel; i++ {
+			wg.Add(1)
+			go runOpenLoopWorker(ctx, &wg, cmdParts, rdb, scheduleChan, resultsChan)
+		}
+	} else {
+		for i := 0; i < *parallel; i++ {
+			wg.Add(1)
+			go worker(ctx, &wg, i, cmdParts, rdb, resultsChan, workerRPSLimit)
+		}
 	}
 
 	go func() {
@@ -275,10 +712,19 @@ COLLECT_LOOP:
 				break COLLECT_LOOP
 			}
 			atomic.AddInt64(&collectedOpsAtomic, 1)
-			allLatencies = append(allLatencies, result.Latency) // Collect all latencies
+			recordLatency(correctedHist, result.Latency)
+			recordLatency(uncorrectedHist, result.ActualLatency)
+			window.record(result.Latency, result.Error != nil)
+			if metrics != nil {
+				metrics.opsTotal.Inc()
+				metrics.latencySeconds.Observe(result.Latency.Seconds())
+			}
 			if result.Error != nil {
 				atomic.AddInt64(&failedOpsAtomic, 1)
 				// log.Printf("Command error: %v", result.Error) // Can be very noisy
+				if metrics != nil {
+					metrics.failuresTotal.Inc()
+				}
 			} else {
 				atomic.AddInt64(&successfulOpsAtomic, 1)
 			}
@@ -315,18 +761,13 @@ COLLECT_LOOP:
 		fmt.Printf("Overall Ops/Second (successful): %.2f\n", float64(finalSuccessfulOps)/actualDuration.Seconds())
 	}
 
-	// Calculate and print latency statistics
-	if len(allLatencies) > 0 {
-		latenciesNanos := make([]float64, len(allLatencies))
-		for i, l := range allLatencies {
-			latenciesNanos[i] = float64(l.Nanoseconds())
-		}
-
-		minLat, maxLat, meanLat, p50Lat, p95Lat, p99Lat, p999Lat, err := calculateLatencyStats(latenciesNanos)
+	// Calculate and print latency statistics from the corrected histogram.
+	if correctedHist.TotalCount() > 0 {
+		minLat, maxLat, meanLat, p50Lat, p95Lat, p99Lat, p999Lat, err := calculateLatencyStats(correctedHist)
 		if err != nil {
 			log.Printf("Error calculating latency stats: %v", err)
 		} else {
-			fmt.Println("\n--- Latency Statistics ---")
+			fmt.Println("\n--- Latency Statistics (corrected) ---")
 			fmt.Printf("  Min: %s\n", minLat.String())
 			fmt.Printf("  Max: %s\n", maxLat.String())
 			fmt.Printf("  Mean: %s\n", meanLat.String())
@@ -334,6 +775,38 @@ COLLECT_LOOP:
 			fmt.Printf("  P95: %s\n", p95Lat.String())
 			fmt.Printf("  P99: %s\n", p99Lat.String())
 			fmt.Printf("  P99.9: %s\n", p999Lat.String())
+
+			if *summaryFile != "" {
+				summary := finalSummary{
+					TotalOps:        finalTotalOps,
+					SuccessfulOps:   finalSuccessfulOps,
+					FailedOps:       finalFailedOps,
+					DurationSeconds: actualDuration.Seconds(),
+					OverallRPS:      float64(finalSuccessfulOps) / actualDuration.Seconds(),
+					MinNanos:        minLat.Nanoseconds(),
+					MaxNanos:        maxLat.Nanoseconds(),
+					MeanNanos:       meanLat.Nanoseconds(),
+					P50Nanos:        p50Lat.Nanoseconds(),
+					P95Nanos:        p95Lat.Nanoseconds(),
+					P99Nanos:        p99Lat.Nanoseconds(),
+					P999Nanos:       p999Lat.Nanoseconds(),
+				}
+				if err := writeSummaryFile(*summaryFile, summary); err != nil {
+					log.Printf("Error writing -summaryFile: %v", err)
+				} else {
+					fmt.Printf("\nWrote summary to %s\n", *summaryFile)
+				}
+			}
+		}
+
+		printLatencyDistribution(correctedHist, uncorrectedHist)
+
+		if *hdrOut != "" {
+			if err := writeHdrLog(*hdrOut, correctedHist); err != nil {
+				log.Printf("Error writing -hdrOut: %v", err)
+			} else {
+				fmt.Printf("\nWrote latency histogram to %s\n", *hdrOut)
+			}
 		}
 	} else {
 		fmt.Println("\nNo latency data collected.")
@@ -5,7 +5,10 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"math/rand"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,52 +16,484 @@ import (
 )
 
 var (
-	host       = flag.String("host", "localhost", "Redis host")
-	port       = flag.Int("port", 6379, "Redis port")
+	host       = flag.String("host", "localhost", "Redis host (mode=single)")
+	port       = flag.Int("port", 6379, "Redis port (mode=single)")
 	goroutines = flag.Int("goroutines", 4, "Number of concurrent goroutines")
 	operations = flag.Int("operations", 10000, "Number of operations per goroutine")
 	password   = flag.String("password", "", "Redis password (optional)")
+
+	mode             = flag.String("mode", "single", "Deployment mode: single|cluster|sentinel")
+	addrs            = flag.String("addrs", "", "Comma-separated cluster seed addresses (mode=cluster)")
+	master           = flag.String("master", "", "Sentinel master name (mode=sentinel)")
+	sentinelAddrs    = flag.String("sentinelAddrs", "", "Comma-separated sentinel addresses (mode=sentinel)")
+	hashtag          = flag.String("hashtag", "", "Hashtag prefix to wrap keys in {tag}:... for slot targeting")
+	slotDistribution = flag.String("slotDistribution", "uniform", "Key slot distribution: uniform|single-slot|multi-slot")
+
+	workload  = flag.String("workload", "set-get", "Workload profile: set-get|read-heavy|write-heavy|incr|lpush-lpop|hset-hget|zadd-zrangebyscore")
+	getRatio  = flag.Float64("getRatio", 0.5, "Fraction of ops that are reads, for the set-get/read-heavy/write-heavy profiles (0.0-1.0). Defaults per -workload (set-get=0.5, read-heavy=0.95, write-heavy=0.05) unless set explicitly")
+	valueSize = flag.String("valueSize", "fixed:64", "Value size distribution: fixed:N | uniform:MIN-MAX | zipf:s=S,v=V,imax=MAX")
+	keyspace  = flag.Int("keyspace", 0, "Bound the key universe to N keys so GETs can hit pre-populated keys (0 = unbounded, legacy per-op unique keys)")
+	keyDist   = flag.String("keyDist", "uniform", "Key access distribution when -keyspace>0: uniform|zipf|sequential")
+	cleanup   = flag.String("cleanup", "none", "Post-run cleanup of benchmark keys: scan|flushdb|none")
 )
 
+// OperationResult is the per-goroutine summary handed back on resultsChan.
 type OperationResult struct {
 	successfulOps int64
 	totalLatency  time.Duration
+	// opLatencies is only populated in mode=cluster, where we need to
+	// attribute each operation's latency to the slot (and therefore node)
+	// it landed on.
+	opLatencies []opLatency
+}
+
+type opLatency struct {
+	slot    int
+	latency time.Duration
+}
+
+// nodeStats accumulates per-shard results when running against a cluster.
+type nodeStats struct {
+	ops       int64
+	latencies []time.Duration
+}
+
+func newRedisClient(ctx context.Context) (redis.UniversalClient, error) {
+	switch *mode {
+	case "single":
+		redisAddr := *host + ":" + strconv.Itoa(*port)
+		return redis.NewClient(&redis.Options{
+			Addr:     redisAddr,
+			Password: *password,
+			DB:       0,
+			PoolSize: *goroutines + 5,
+		}), nil
+	case "cluster":
+		if *addrs == "" {
+			return nil, fmt.Errorf("-addrs is required for mode=cluster")
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    strings.Split(*addrs, ","),
+			Password: *password,
+			PoolSize: *goroutines + 5,
+		}), nil
+	case "sentinel":
+		if *master == "" || *sentinelAddrs == "" {
+			return nil, fmt.Errorf("-master and -sentinelAddrs are required for mode=sentinel")
+		}
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    *master,
+			SentinelAddrs: strings.Split(*sentinelAddrs, ","),
+			Password:      *password,
+			PoolSize:      *goroutines + 5,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown -mode %q (want single|cluster|sentinel)", *mode)
+	}
+}
+
+// crc16 implements the CRC16-CCITT (XMODEM) variant used by Redis Cluster
+// for key hash slot assignment (see keyHashSlot in the Redis source).
+func crc16(buf string) uint16 {
+	var crc uint16
+	for i := 0; i < len(buf); i++ {
+		crc ^= uint16(buf[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = (crc << 1) ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// keyHashSlot mirrors Redis Cluster's keyHashSlot(): hash only the part of
+// the key between the first '{' and the following '}' when present and
+// non-empty, otherwise hash the whole key.
+func keyHashSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start != -1 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key) % 16384)
+}
+
+// newValueSizeSampler parses -valueSize ("fixed:N", "uniform:MIN-MAX", or
+// "zipf:s=S,v=V,imax=MAX") into a function that returns a value size in
+// bytes each time it's called.
+func newValueSizeSampler(spec string, rng *rand.Rand) (func() int, error) {
+	switch {
+	case strings.HasPrefix(spec, "fixed:"):
+		n, err := strconv.Atoi(strings.TrimPrefix(spec, "fixed:"))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid -valueSize %q: want fixed:N", spec)
+		}
+		return func() int { return n }, nil
+
+	case strings.HasPrefix(spec, "uniform:"):
+		parts := strings.SplitN(strings.TrimPrefix(spec, "uniform:"), "-", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid -valueSize %q: want uniform:MIN-MAX", spec)
+		}
+		min, err1 := strconv.Atoi(parts[0])
+		max, err2 := strconv.Atoi(parts[1])
+		if err1 != nil || err2 != nil || min <= 0 || max < min {
+			return nil, fmt.Errorf("invalid -valueSize %q: want uniform:MIN-MAX", spec)
+		}
+		return func() int { return min + rng.Intn(max-min+1) }, nil
+
+	case strings.HasPrefix(spec, "zipf:"):
+		params, err := parseParamString(strings.TrimPrefix(spec, "zipf:"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid -valueSize %q: %w", spec, err)
+		}
+		s, v, imax, err := parseZipfParams(params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -valueSize %q: %w", spec, err)
+		}
+		z := rand.NewZipf(rng, s, v, imax)
+		if z == nil {
+			return nil, fmt.Errorf("invalid -valueSize %q: zipf parameters out of range", spec)
+		}
+		return func() int { return int(z.Uint64()) + 1 }, nil // +1 so values are never zero-length
+
+	default:
+		return nil, fmt.Errorf("unknown -valueSize %q (want fixed:N | uniform:MIN-MAX | zipf:s=S,v=V,imax=MAX)", spec)
+	}
+}
+
+// parseParamString parses a "k1=v1,k2=v2" parameter list, as used by the
+// zipf value-size spec.
+func parseParamString(s string) (map[string]string, error) {
+	params := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("malformed parameter %q", pair)
+		}
+		params[kv[0]] = kv[1]
+	}
+	return params, nil
+}
+
+func parseZipfParams(params map[string]string) (s, v float64, imax uint64, err error) {
+	if s, err = strconv.ParseFloat(params["s"], 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("missing/invalid s: %w", err)
+	}
+	if v, err = strconv.ParseFloat(params["v"], 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("missing/invalid v: %w", err)
+	}
+	if imax, err = strconv.ParseUint(params["imax"], 10, 64); err != nil {
+		return 0, 0, 0, fmt.Errorf("missing/invalid imax: %w", err)
+	}
+	return s, v, imax, nil
+}
+
+// newKeyIndexSampler returns a function sampling a key index in [0,
+// *keyspace) according to -keyDist, or nil if -keyspace is unbounded (0), in
+// which case callers fall back to the legacy per-op unique key scheme.
+func newKeyIndexSampler(rng *rand.Rand) func() int {
+	if *keyspace <= 0 {
+		return nil
+	}
+	switch *keyDist {
+	case "sequential":
+		var next int
+		return func() int {
+			idx := next % *keyspace
+			next++
+			return idx
+		}
+	case "zipf":
+		z := rand.NewZipf(rng, 1.1, 1, uint64(*keyspace-1))
+		return func() int { return int(z.Uint64()) }
+	default: // uniform
+		return func() int { return rng.Intn(*keyspace) }
+	}
+}
+
+// generateKey builds the benchmark key for a given goroutine/operation pair,
+// optionally wrapping it in a Redis Cluster hashtag so that -slotDistribution
+// can steer it to a single slot, a handful of slots, or let it fall wherever
+// it naturally hashes. When keyIndexer is non-nil the key is drawn from the
+// bounded -keyspace universe instead of being unique per operation.
+func generateKey(routineID, opID int, keyIndexer func() int) string {
+	var base string
+	if keyIndexer != nil {
+		base = fmt.Sprintf("goroutine-key-%d", keyIndexer())
+	} else {
+		base = fmt.Sprintf("goroutine-%d-op-%d", routineID, opID)
+	}
+
+	switch *slotDistribution {
+	case "single-slot":
+		tag := *hashtag
+		if tag == "" {
+			tag = "bench"
+		}
+		return fmt.Sprintf("{%s}:%s", tag, base)
+	case "multi-slot":
+		// Bucket by a hash of the key itself, not the goroutine, so the
+		// spread depends on the actual keys in play rather than collapsing
+		// every op from a given goroutine onto the same slot.
+		const buckets = 16
+		tag := fmt.Sprintf("slot-%d", crc16(base)%buckets)
+		if *hashtag != "" {
+			tag = *hashtag + "-" + tag
+		}
+		return fmt.Sprintf("{%s}:%s", tag, base)
+	default: // uniform
+		if *hashtag != "" {
+			return fmt.Sprintf("{%s}:%s", *hashtag, base)
+		}
+		return base
+	}
+}
+
+// prePopulateKeyspace SETs every key in the bounded -keyspace universe before
+// the timed benchmark starts, so GETs sampled during the run land on keys
+// that actually exist instead of drawing redis.Nil misses.
+func prePopulateKeyspace(ctx context.Context, rdb redis.UniversalClient, valueSampler func() int) error {
+	fmt.Printf("Pre-populating keyspace (%d keys)...\n", *keyspace)
+	for i := 0; i < *keyspace; i++ {
+		idx := i
+		key := generateKey(0, 0, func() int { return idx })
+		if err := rdb.Set(ctx, key, strings.Repeat("v", valueSampler()), 0).Err(); err != nil {
+			return fmt.Errorf("key %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// buildSlotToNodeMap reads ClusterSlots() once so per-operation latencies can
+// be attributed to the node that owns the slot they hit.
+func buildSlotToNodeMap(ctx context.Context, rdb redis.UniversalClient) (map[int]string, error) {
+	clusterClient, ok := rdb.(*redis.ClusterClient)
+	if !ok {
+		return nil, fmt.Errorf("per-node reporting requires mode=cluster")
+	}
+	slots, err := clusterClient.ClusterSlots(ctx).Result()
+	if err != nil {
+		return nil, fmt.Errorf("ClusterSlots failed: %w", err)
+	}
+	slotToNode := make(map[int]string)
+	for _, s := range slots {
+		if len(s.Nodes) == 0 {
+			continue
+		}
+		addr := s.Nodes[0].Addr // first node in the slot range is the master
+		for slot := s.Start; slot <= s.End; slot++ {
+			slotToNode[slot] = addr
+		}
+	}
+	return slotToNode, nil
+}
+
+// applyWorkloadDefaults gives the read-heavy/write-heavy profiles their own
+// implied -getRatio (0.95/0.05) so they actually behave differently from
+// set-get's 0.5 split, without overriding a -getRatio the user set explicitly.
+func applyWorkloadDefaults() {
+	var getRatioExplicit bool
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "getRatio" {
+			getRatioExplicit = true
+		}
+	})
+	if getRatioExplicit {
+		return
+	}
+	switch *workload {
+	case "read-heavy":
+		*getRatio = 0.95
+	case "write-heavy":
+		*getRatio = 0.05
+	}
+}
+
+// runOp executes one operation of the configured -workload against key,
+// returning whether it succeeded. set-get/read-heavy/write-heavy sample a
+// GET vs SET per -getRatio; incr issues a single INCR; the remaining profiles
+// exercise a fixed write-then-read pair of the corresponding Redis data type.
+func runOp(ctx context.Context, rdb redis.UniversalClient, key string, j int, rng *rand.Rand, valueSampler func() int) error {
+	switch *workload {
+	case "set-get", "read-heavy", "write-heavy":
+		if rng.Float64() < *getRatio {
+			if *keyspace <= 0 {
+				// Unbounded keyspace: key is unique per op, so there's no
+				// pre-populated universe for this GET to hit. Write it first,
+				// mirroring the original SET-then-GET loop, so the sampled
+				// read still succeeds.
+				if err := rdb.Set(ctx, key, strings.Repeat("v", valueSampler()), 0).Err(); err != nil {
+					return err
+				}
+			}
+			return rdb.Get(ctx, key).Err()
+		}
+		return rdb.Set(ctx, key, strings.Repeat("v", valueSampler()), 0).Err()
+	case "incr":
+		return rdb.Incr(ctx, key).Err()
+	case "lpush-lpop":
+		if err := rdb.LPush(ctx, key, strings.Repeat("v", valueSampler())).Err(); err != nil {
+			return err
+		}
+		return rdb.LPop(ctx, key).Err()
+	case "hset-hget":
+		field := fmt.Sprintf("f-%d", j%16)
+		if err := rdb.HSet(ctx, key, field, strings.Repeat("v", valueSampler())).Err(); err != nil {
+			return err
+		}
+		return rdb.HGet(ctx, key, field).Err()
+	case "zadd-zrangebyscore":
+		member := fmt.Sprintf("m-%d", j)
+		if err := rdb.ZAdd(ctx, key, &redis.Z{Score: rng.Float64() * 1000, Member: member}).Err(); err != nil {
+			return err
+		}
+		return rdb.ZRangeByScore(ctx, key, &redis.ZRangeBy{Min: "0", Max: "1000"}).Err()
+	default:
+		return fmt.Errorf("unknown -workload %q", *workload)
+	}
+}
+
+// runCleanup removes benchmark keys per -cleanup. "scan" avoids blocking the
+// server the way a bare KEYS would, walking matches with SCAN and deleting
+// them in pipelined single-key DELs. In mode=cluster a SCAN only walks one
+// node and a multi-key DEL across arbitrary keys would CROSSSLOT, so cleanup
+// iterates every master and deletes one key per pipelined command instead.
+func runCleanup(ctx context.Context, rdb redis.UniversalClient) error {
+	switch *cleanup {
+	case "none":
+		return nil
+	case "flushdb":
+		return rdb.FlushDB(ctx).Err()
+	case "scan":
+		if clusterClient, ok := rdb.(*redis.ClusterClient); ok {
+			return clusterClient.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+				return scanAndDeleteKeys(ctx, master)
+			})
+		}
+		return scanAndDeleteKeys(ctx, rdb)
+	default:
+		return fmt.Errorf("unknown -cleanup %q (want scan|flushdb|none)", *cleanup)
+	}
+}
+
+// scanAndDeleteKeys walks a single node with SCAN and deletes matches as
+// single-key DELs inside a pipeline, so it never sends a multi-key command
+// spanning keys that could hash to different cluster slots. The match
+// pattern looks for "goroutine-" anywhere in the key, not just as a prefix,
+// since generateKey wraps it in a "{tag}:" hashtag whenever -slotDistribution
+// or -hashtag is in play.
+func scanAndDeleteKeys(ctx context.Context, rdb redis.UniversalClient) error {
+	const batchSize = 500
+	iter := rdb.Scan(ctx, 0, "*goroutine-*", 1000).Iterator()
+	pipe := rdb.Pipeline()
+	pending := 0
+	for iter.Next(ctx) {
+		pipe.Del(ctx, iter.Val())
+		pending++
+		if pending >= batchSize {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+			pending = 0
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if pending > 0 {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func percentile(sortedLatencies []time.Duration, p float64) time.Duration {
+	if len(sortedLatencies) == 0 {
+		return 0
+	}
+	idx := int(p / 100 * float64(len(sortedLatencies)))
+	if idx >= len(sortedLatencies) {
+		idx = len(sortedLatencies) - 1
+	}
+	return sortedLatencies[idx]
 }
 
 func main() {
 	flag.Parse()
+	applyWorkloadDefaults()
 
 	ctx := context.Background()
 
-	redisAddr := *host + ":" + strconv.Itoa(*port)
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: *password,       // no password set
-		DB:       0,               // use default DB
-		PoolSize: *goroutines + 5, // Connection pool size
-	})
+	rdb, err := newRedisClient(ctx)
+	if err != nil {
+		log.Fatalf("Could not build Redis client: %v", err)
+	}
 
 	// Test connection
-	_, err := rdb.Ping(ctx).Result()
+	_, err = rdb.Ping(ctx).Result()
 	if err != nil {
 		log.Fatalf("Could not connect to Redis: %v", err)
 	}
-	fmt.Println("Successfully connected to Redis at", redisAddr)
+	fmt.Println("Successfully connected to Redis in", *mode, "mode")
 
 	fmt.Printf("\nConfiguration:\n")
-	fmt.Printf("  Host: %s\n", *host)
-	fmt.Printf("  Port: %d\n", *port)
+	fmt.Printf("  Mode: %s\n", *mode)
+	switch *mode {
+	case "single":
+		fmt.Printf("  Host: %s\n", *host)
+		fmt.Printf("  Port: %d\n", *port)
+	case "cluster":
+		fmt.Printf("  Addrs: %s\n", *addrs)
+		fmt.Printf("  Slot distribution: %s\n", *slotDistribution)
+		if *hashtag != "" {
+			fmt.Printf("  Hashtag: %s\n", *hashtag)
+		}
+	case "sentinel":
+		fmt.Printf("  Master: %s\n", *master)
+		fmt.Printf("  Sentinel addrs: %s\n", *sentinelAddrs)
+	}
 	fmt.Printf("  Goroutines: %d\n", *goroutines)
 	fmt.Printf("  Operations per goroutine: %d\n", *operations)
 	fmt.Printf("  Total operations: %d\n", int64(*goroutines)*int64(*operations))
+	fmt.Printf("  Workload: %s (getRatio=%.2f)\n", *workload, *getRatio)
+	fmt.Printf("  Value size: %s\n", *valueSize)
+	if *keyspace > 0 {
+		fmt.Printf("  Keyspace: %d keys (keyDist=%s)\n", *keyspace, *keyDist)
+	}
 	if *password != "" {
 		fmt.Println("  Password provided.")
 	}
 	fmt.Println()
 
+	// Validate -valueSize once up front so a bad spec fails fast rather than
+	// mid-run inside a goroutine.
+	valueSampler, err := newValueSizeSampler(*valueSize, rand.New(rand.NewSource(1)))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// set-get/read-heavy/write-heavy sample GETs by key; with -keyspace
+	// bounded (>0), pre-populate the universe so sampled GETs hit keys a
+	// prior SET actually wrote rather than drawing misses. With -keyspace
+	// unbounded (0, the legacy per-op-unique-key mode), runOp instead writes
+	// each sampled read's key just before GETting it.
+	readCapableWorkload := *workload == "set-get" || *workload == "read-heavy" || *workload == "write-heavy"
+	if readCapableWorkload && *keyspace > 0 {
+		if err := prePopulateKeyspace(ctx, rdb, valueSampler); err != nil {
+			log.Fatalf("Pre-populating keyspace: %v", err)
+		}
+	}
+
 	var wg sync.WaitGroup
 	resultsChan := make(chan OperationResult, *goroutines)
 
+	clusterMode := *mode == "cluster"
+
 	overallStartTime := time.Now()
 
 	for i := 0; i < *goroutines; i++ {
@@ -68,40 +503,32 @@ func main() {
 
 			var localSuccessfulOps int64
 			var localTotalLatency time.Duration
+			var localOpLatencies []opLatency
 
-			// Use a separate Redis client for each goroutine to avoid contention on a single client
-			// This is generally recommended when each goroutine performs many operations.
-			// However, for this benchmark, we will use the shared rdb client from the pool.
-			// If connection pooling is efficient, this should be fine.
-			// For extreme load, creating a client per goroutine might be considered,
-			// but ensure proper closing or pooling for those too.
+			rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(routineID)))
+			valueSampler, _ := newValueSizeSampler(*valueSize, rng) // already validated above
+			keyIndexer := newKeyIndexSampler(rng)
 
 			for j := 0; j < *operations; j++ {
-				key := fmt.Sprintf("goroutine-%d-op-%d", routineID, j)
-				value := fmt.Sprintf("value-%d", j)
+				key := generateKey(routineID, j, keyIndexer)
 
 				opStartTime := time.Now()
-				err := rdb.Set(ctx, key, value, 0).Err()
-				if err != nil {
-					// log.Printf("SET error for key %s in goroutine %d: %v", key, routineID, err)
-					continue
-				}
-
-				retrievedValue, err := rdb.Get(ctx, key).Result()
-				if err != nil {
-					// log.Printf("GET error for key %s in goroutine %d: %v", key, routineID, err)
-					continue
-				}
-				opEndTime := time.Now()
+				err := runOp(ctx, rdb, key, j, rng, valueSampler)
+				latency := time.Since(opStartTime)
 
-				if retrievedValue == value {
+				if err == nil {
 					localSuccessfulOps++
-					localTotalLatency += opEndTime.Sub(opStartTime)
-				} else {
-					// log.Printf("Data mismatch for key %s in goroutine %d", key, routineID)
+					localTotalLatency += latency
+					if clusterMode {
+						localOpLatencies = append(localOpLatencies, opLatency{slot: keyHashSlot(key), latency: latency})
+					}
 				}
 			}
-			resultsChan <- OperationResult{successfulOps: localSuccessfulOps, totalLatency: localTotalLatency}
+			resultsChan <- OperationResult{
+				successfulOps: localSuccessfulOps,
+				totalLatency:  localTotalLatency,
+				opLatencies:   localOpLatencies,
+			}
 		}(i)
 	}
 
@@ -113,10 +540,12 @@ func main() {
 
 	var totalSuccessfulOps int64
 	var totalAggregatedLatency time.Duration
+	var allOpLatencies []opLatency
 
 	for result := range resultsChan {
 		totalSuccessfulOps += result.successfulOps
 		totalAggregatedLatency += result.totalLatency
+		allOpLatencies = append(allOpLatencies, result.opLatencies...)
 	}
 
 	opsPerSecond := 0.0
@@ -137,16 +566,61 @@ func main() {
 	fmt.Printf("Number of goroutines: %d\n", *goroutines)
 	fmt.Println("------------------------")
 
-	// Attempt to clean up keys (best effort, might be slow for huge number of keys)
-	// Consider disabling for very large benchmarks or using SCAN for production cleanup.
-	// fmt.Println("\nAttempting to clean up benchmark keys...")
-	// cleanupStartTime := time.Now()
-	// for i := 0; i < *goroutines; i++ {
-	// 	for j := 0; j < *operations; j++ {
-	// 		key := fmt.Sprintf("goroutine-%d-op-%d", i, j)
-	// 		rdb.Del(ctx, key)
-	// 	}
-	// }
-	// cleanupDuration := time.Since(cleanupStartTime)
-	// fmt.Printf("Key cleanup took %.3f seconds (best effort).\n", cleanupDuration.Seconds())
+	if clusterMode {
+		printPerNodeStats(ctx, rdb, allOpLatencies, totalDuration)
+	}
+
+	if *cleanup != "none" {
+		fmt.Printf("\nCleaning up benchmark keys (cleanup=%s)...\n", *cleanup)
+		cleanupStart := time.Now()
+		if err := runCleanup(ctx, rdb); err != nil {
+			log.Printf("Cleanup failed: %v", err)
+		} else {
+			fmt.Printf("Cleanup took %.3f seconds.\n", time.Since(cleanupStart).Seconds())
+		}
+	}
+}
+
+// printPerNodeStats groups collected latencies by the cluster node that owns
+// each key's slot and reports per-shard OPS and p99, since a single
+// aggregate number hides hot-shard behavior.
+func printPerNodeStats(ctx context.Context, rdb redis.UniversalClient, allOpLatencies []opLatency, totalDuration time.Duration) {
+	slotToNode, err := buildSlotToNodeMap(ctx, rdb)
+	if err != nil {
+		log.Printf("Could not build per-node stats: %v", err)
+		return
+	}
+
+	byNode := make(map[string]*nodeStats)
+	for _, ol := range allOpLatencies {
+		addr, ok := slotToNode[ol.slot]
+		if !ok {
+			addr = "unknown"
+		}
+		ns, ok := byNode[addr]
+		if !ok {
+			ns = &nodeStats{}
+			byNode[addr] = ns
+		}
+		ns.ops++
+		ns.latencies = append(ns.latencies, ol.latency)
+	}
+
+	fmt.Println("\n--- Per-Node Results ---")
+	nodes := make([]string, 0, len(byNode))
+	for addr := range byNode {
+		nodes = append(nodes, addr)
+	}
+	sort.Strings(nodes)
+	for _, addr := range nodes {
+		ns := byNode[addr]
+		sort.Slice(ns.latencies, func(i, j int) bool { return ns.latencies[i] < ns.latencies[j] })
+		p99 := percentile(ns.latencies, 99)
+		nodeOPS := 0.0
+		if totalDuration.Seconds() > 0 {
+			nodeOPS = float64(ns.ops) / totalDuration.Seconds()
+		}
+		fmt.Printf("  %s: ops=%d OPS=%.2f p99=%s\n", addr, ns.ops, nodeOPS, p99)
+	}
+	fmt.Println("------------------------")
 }